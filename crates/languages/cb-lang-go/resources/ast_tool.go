@@ -6,18 +6,39 @@
 // Usage:
 //   echo "package main\nimport \"fmt\"" | go run ast_tool.go analyze-imports
 //   echo "package main\nfunc foo() {}" | go run ast_tool.go extract-symbols
+//   echo '{"source":"...","operations":[{"op":"add","path":"fmt"}]}' | go run ast_tool.go rewrite-imports
+//   echo '{"source":"...","start":10,"end":10}' | go run ast_tool.go find-enclosing
+//   go run ast_tool.go call-graph --rta ./cmd/myapp
+//   echo '{"source":"...","offset":42}' | go run ast_tool.go fill-struct
+//   go run ast_tool.go extract-messages --func i18n.T ./cmd/myapp
+//   go run ast_tool.go unused-symbols --scope module ./...
 
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/importer"
 	"go/parser"
+	"go/printer"
 	"go/token"
+	"go/types"
 	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // ImportInfo represents a single import statement
@@ -282,63 +303,1623 @@ func extractSymbols(source string) ([]SymbolInfo, error) {
 	return symbols, nil
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <command>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Commands:\n")
-		fmt.Fprintf(os.Stderr, "  analyze-imports  Parse Go source from stdin and output import information as JSON\n")
-		fmt.Fprintf(os.Stderr, "  extract-symbols  Parse Go source from stdin and output symbol information as JSON\n")
-		os.Exit(1)
+// ImportOp is a single mutation to apply to a file's import declarations.
+type ImportOp struct {
+	Op       string  `json:"op"` // add, delete, rename, set-alias
+	Path     string  `json:"path"`
+	Alias    *string `json:"alias"`     // desired alias for add/set-alias
+	NewAlias *string `json:"new_alias"` // new alias for rename
+}
+
+// RewriteImportsRequest is the stdin payload for the rewrite-imports command.
+type RewriteImportsRequest struct {
+	Source     string     `json:"source"`
+	Operations []ImportOp `json:"operations"`
+}
+
+// RewriteConflict describes an operation that could not be applied.
+type RewriteConflict struct {
+	Op     ImportOp `json:"op"`
+	Reason string   `json:"reason"`
+}
+
+// RewriteImportsResult is the output of a successful rewrite-imports run.
+type RewriteImportsResult struct {
+	Source    string            `json:"source"`
+	Conflicts []RewriteConflict `json:"conflicts"`
+}
+
+// rewriteImports parses source once, applies every operation in order against
+// the same AST, and reprints once. Operations that cannot be applied (alias
+// collisions, deleting a still-used package, etc.) are collected as conflicts
+// rather than aborting the whole patch.
+func rewriteImports(req RewriteImportsRequest) (RewriteImportsResult, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", req.Source, parser.ParseComments)
+	if err != nil {
+		return RewriteImportsResult{}, fmt.Errorf("failed to parse Go source: %w", err)
 	}
 
-	command := os.Args[1]
+	var conflicts []RewriteConflict
+	for _, op := range req.Operations {
+		if conflict := applyImportOp(file, op); conflict != "" {
+			conflicts = append(conflicts, RewriteConflict{Op: op, Reason: conflict})
+		}
+	}
 
-	switch command {
-	case "analyze-imports":
-		// Read source from stdin
-		sourceBytes, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-			os.Exit(1)
+	pruneEmptyImportDecls(file)
+
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, fset, file); err != nil {
+		return RewriteImportsResult{}, fmt.Errorf("failed to print rewritten source: %w", err)
+	}
+
+	return RewriteImportsResult{Source: buf.String(), Conflicts: conflicts}, nil
+}
+
+// applyImportOp applies a single operation to file in place, returning a
+// non-empty conflict reason if it could not be applied.
+func applyImportOp(file *ast.File, op ImportOp) string {
+	switch op.Op {
+	case "add":
+		if findImportSpec(file, op.Path) != nil {
+			return fmt.Sprintf("package %q is already imported", op.Path)
+		}
+		if op.Alias != nil && findImportByAlias(file, *op.Alias) != nil {
+			return fmt.Sprintf("alias %q already refers to another import", *op.Alias)
 		}
+		addImportSpec(file, op.Path, op.Alias)
+		return ""
 
-		source := string(sourceBytes)
+	case "delete":
+		spec := findImportSpec(file, op.Path)
+		if spec == nil {
+			return fmt.Sprintf("package %q is not imported", op.Path)
+		}
+		name := importedName(spec, op.Path)
+		if identifierIsUsed(file, name) {
+			return fmt.Sprintf("package %q is still referenced as %q", op.Path, name)
+		}
+		removeImportSpec(file, spec)
+		return ""
 
-		// Analyze imports
-		imports, err := analyzeImports(source)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error analyzing imports: %v\n", err)
-			os.Exit(1)
+	case "rename":
+		spec := findImportSpec(file, op.Path)
+		if spec == nil {
+			return fmt.Sprintf("package %q is not imported", op.Path)
+		}
+		if op.NewAlias == nil || *op.NewAlias == "" {
+			return "rename requires a new_alias"
+		}
+		if existing := findImportByAlias(file, *op.NewAlias); existing != nil && existing != spec {
+			return fmt.Sprintf("alias %q already refers to another import", *op.NewAlias)
 		}
+		oldName := importedName(spec, op.Path)
+		renameQualifiedIdents(file, oldName, *op.NewAlias)
+		spec.Name = ast.NewIdent(*op.NewAlias)
+		return ""
 
-		// Output as JSON
-		output, err := json.MarshalIndent(imports, "", "  ")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
-			os.Exit(1)
+	case "set-alias":
+		spec := findImportSpec(file, op.Path)
+		if spec == nil {
+			return fmt.Sprintf("package %q is not imported", op.Path)
+		}
+		if op.Alias == nil || *op.Alias == "" {
+			spec.Name = nil
+			return ""
 		}
+		if existing := findImportByAlias(file, *op.Alias); existing != nil && existing != spec {
+			return fmt.Sprintf("alias %q already refers to another import", *op.Alias)
+		}
+		spec.Name = ast.NewIdent(*op.Alias)
+		return ""
 
-		fmt.Println(string(output))
+	default:
+		return fmt.Sprintf("unknown operation %q", op.Op)
+	}
+}
 
-	case "extract-symbols":
-		// Read source from stdin
-		sourceBytes, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-			os.Exit(1)
+// findImportSpec returns the ImportSpec for path, or nil if not imported.
+func findImportSpec(file *ast.File, path string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, `"`) == path {
+			return spec
 		}
+	}
+	return nil
+}
 
-		source := string(sourceBytes)
+// findImportByAlias returns the ImportSpec whose local name is alias.
+func findImportByAlias(file *ast.File, alias string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if spec.Name != nil && spec.Name.Name == alias {
+			return spec
+		}
+	}
+	return nil
+}
 
-		// Extract symbols
-		symbols, err := extractSymbols(source)
+// importedName returns the local identifier a package is referenced by.
+func importedName(spec *ast.ImportSpec, path string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	parts := strings.Split(path, "/")
+	return parts[len(parts)-1]
+}
+
+// identifierIsUsed reports whether name appears as the package in a
+// qualified identifier (pkg.Ident) anywhere in the file.
+func identifierIsUsed(file *ast.File, name string) bool {
+	used := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if used {
+			return false
+		}
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name {
+				used = true
+				return false
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// renameQualifiedIdents rewrites every pkg.Ident reference from oldName to
+// newName so alias renames stay consistent with the rest of the file.
+func renameQualifiedIdents(file *ast.File, oldName, newName string) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == oldName {
+				ident.Name = newName
+			}
+		}
+		return true
+	})
+}
+
+// addImportSpec inserts a new import spec into the first existing import
+// declaration (creating a parenthesized one if the file has none).
+func addImportSpec(file *ast.File, path string, alias *string) {
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", path)}}
+	if alias != nil {
+		spec.Name = ast.NewIdent(*alias)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		gen.Specs = append(gen.Specs, spec)
+		if gen.Lparen == token.NoPos {
+			gen.Lparen = gen.TokPos + 1
+		}
+		file.Imports = append(file.Imports, spec)
+		return
+	}
+
+	gen := &ast.GenDecl{Tok: token.IMPORT, Lparen: token.Pos(1), Specs: []ast.Spec{spec}}
+	newDecls := make([]ast.Decl, 0, len(file.Decls)+1)
+	newDecls = append(newDecls, gen)
+	newDecls = append(newDecls, file.Decls...)
+	file.Decls = newDecls
+	file.Imports = append(file.Imports, spec)
+}
+
+// removeImportSpec deletes spec from its declaration and from file.Imports.
+func removeImportSpec(file *ast.File, spec *ast.ImportSpec) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for i, s := range gen.Specs {
+			if s == spec {
+				gen.Specs = append(gen.Specs[:i], gen.Specs[i+1:]...)
+				break
+			}
+		}
+	}
+	for i, s := range file.Imports {
+		if s == spec {
+			file.Imports = append(file.Imports[:i], file.Imports[i+1:]...)
+			break
+		}
+	}
+}
+
+// pruneEmptyImportDecls drops the grouping parens (and the decl itself) for
+// any import declaration left with no specs after deletions.
+func pruneEmptyImportDecls(file *ast.File) {
+	kept := make([]ast.Decl, 0, len(file.Decls))
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && len(gen.Specs) == 0 {
+			continue
+		}
+		kept = append(kept, decl)
+	}
+	file.Decls = kept
+}
+
+// FindEnclosingRequest is the stdin payload for the find-enclosing command.
+// Start and End are 0-based byte offsets into Source.
+type FindEnclosingRequest struct {
+	Source string `json:"source"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+}
+
+// EnclosingNode describes one node along the ancestor chain.
+type EnclosingNode struct {
+	Kind     string   `json:"kind"` // concrete ast.Node type, e.g. FuncDecl
+	Name     string   `json:"name,omitempty"`
+	Location Location `json:"location"`
+}
+
+// findEnclosingPath returns the ancestor chain from *ast.File down to the
+// smallest node that fully contains [start, end). It does not depend on
+// astutil: it walks the tree with ast.Inspect, descending only into nodes
+// whose Pos/End already contain the target interval, and remembers the
+// deepest chain reached.
+func findEnclosingPath(req FindEnclosingRequest) ([]EnclosingNode, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", req.Source, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+
+	tokFile := fset.File(file.Pos())
+	start := token.Pos(tokFile.Base() + req.Start)
+	end := token.Pos(tokFile.Base() + req.End)
+
+	var path, deepest []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			path = path[:len(path)-1]
+			return false
+		}
+		if !nodeEncloses(n, start, end) {
+			return false
+		}
+		path = append(path, n)
+		if len(path) > len(deepest) {
+			deepest = append([]ast.Node(nil), path...)
+		}
+		return true
+	})
+
+	result := make([]EnclosingNode, 0, len(deepest))
+	for _, n := range deepest {
+		pos := fset.Position(n.Pos())
+		endPos := fset.Position(n.End())
+		result = append(result, EnclosingNode{
+			Kind: nodeKind(n),
+			Name: nodeName(n),
+			Location: Location{
+				StartLine:   pos.Line,
+				StartColumn: pos.Column - 1,
+				EndLine:     endPos.Line,
+				EndColumn:   endPos.Column - 1,
+			},
+		})
+	}
+	return result, nil
+}
+
+// nodeEncloses reports whether n fully contains [start, end). A zero-width
+// interval (a cursor position) counts as contained when it sits anywhere
+// from n's first character through its last one; a cursor at n.End(), just
+// past the node, is not contained. So a cursor placed right before a token
+// still resolves to that token's node rather than only its parent.
+func nodeEncloses(n ast.Node, start, end token.Pos) bool {
+	if start == end {
+		return n.Pos() <= start && end < n.End()
+	}
+	return n.Pos() <= start && end <= n.End()
+}
+
+// nodeKind returns the bare ast.Node type name, e.g. "FuncDecl".
+func nodeKind(n ast.Node) string {
+	kind := fmt.Sprintf("%T", n)
+	kind = strings.TrimPrefix(kind, "*ast.")
+	return strings.TrimPrefix(kind, "ast.")
+}
+
+// nodeName extracts the identifying name for node kinds that have one.
+func nodeName(n ast.Node) string {
+	switch d := n.(type) {
+	case *ast.FuncDecl:
+		return d.Name.Name
+	case *ast.TypeSpec:
+		return d.Name.Name
+	case *ast.ValueSpec:
+		if len(d.Names) > 0 {
+			return d.Names[0].Name
+		}
+	case *ast.Ident:
+		return d.Name
+	case *ast.ImportSpec:
+		return strings.Trim(d.Path.Value, `"`)
+	case *ast.Field:
+		if len(d.Names) > 0 {
+			return d.Names[0].Name
+		}
+	case *ast.LabeledStmt:
+		return d.Label.Name
+	}
+	return ""
+}
+
+// CallSite is one outgoing call edge, resolved conservatively (CHA/RTA treat
+// interface and indirect calls as reaching every possible implementation).
+type CallSite struct {
+	Callee   string   `json:"callee"`
+	Location Location `json:"location"`
+}
+
+// CallGraphFunc is a single function or method node in the call graph.
+type CallGraphFunc struct {
+	Name     string     `json:"name"` // fully-qualified, e.g. pkg.Func or (*pkg.Type).Method
+	Receiver string     `json:"receiver,omitempty"`
+	Location Location   `json:"location"`
+	Callees  []CallSite `json:"callees"`
+}
+
+// buildCallGraph loads pkgPath, builds its SSA form, and computes a call
+// graph with CHA by default, or RTA (seeded from main) when useRTA is set.
+// When roots is non-empty the result is pruned to functions reachable from
+// those entry points. The reported nodes are restricted to the packages
+// named by pkgPath itself (dependencies are only visible as callees), and
+// the returned slice is sorted for stable diffing.
+func buildCallGraph(pkgPath string, roots []string, useRTA bool) ([]CallGraphFunc, error) {
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package %q: %w", pkgPath, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("package %q has type errors", pkgPath)
+	}
+
+	prog, ssaPkgs := ssautil.AllPackages(pkgs, ssa.SanityCheckFunctions)
+	prog.Build()
+
+	var graph *callgraph.Graph
+	if useRTA {
+		mainFunc := findMainFunc(ssaPkgs)
+		if mainFunc == nil {
+			return nil, fmt.Errorf("--rta requires a main function in %q", pkgPath)
+		}
+		graph = rta.Analyze([]*ssa.Function{mainFunc}, true).CallGraph
+	} else {
+		graph = cha.CallGraph(prog)
+	}
+
+	if len(roots) > 0 {
+		graph, err = pruneToRoots(graph, roots)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error extracting symbols: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
+	}
 
-		// Output as JSON
-		output, err := json.MarshalIndent(symbols, "", "  ")
+	// ssautil.AllPackages builds SSA for the whole transitive program, so
+	// graph.Nodes also contains every dependency (fmt, runtime, reflect,
+	// ...). Only report nodes that belong to one of the packages the caller
+	// actually asked for; callees can still point outside that set.
+	requestedPkgs := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		requestedPkgs[p.PkgPath] = true
+	}
+
+	funcs := make([]CallGraphFunc, 0, len(graph.Nodes))
+	for fn, node := range graph.Nodes {
+		if fn == nil || fn.Pkg == nil || !requestedPkgs[fn.Pkg.Pkg.Path()] {
+			continue
+		}
+		pos := prog.Fset.Position(fn.Pos())
+		cgFn := CallGraphFunc{
+			Name:     fn.RelString(nil),
+			Location: Location{StartLine: pos.Line, StartColumn: pos.Column - 1},
+		}
+		if fn.Signature.Recv() != nil {
+			cgFn.Receiver = fn.Signature.Recv().Type().String()
+		}
+		for _, edge := range node.Out {
+			if edge.Callee.Func == nil || edge.Site == nil {
+				// RTA's synthetic root emits edges for reachable roots with
+				// no real call site to point at.
+				continue
+			}
+			callPos := prog.Fset.Position(edge.Site.Pos())
+			cgFn.Callees = append(cgFn.Callees, CallSite{
+				Callee:   edge.Callee.Func.RelString(nil),
+				Location: Location{StartLine: callPos.Line, StartColumn: callPos.Column - 1},
+			})
+		}
+		sort.Slice(cgFn.Callees, func(i, j int) bool { return cgFn.Callees[i].Callee < cgFn.Callees[j].Callee })
+		funcs = append(funcs, cgFn)
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].Name < funcs[j].Name })
+
+	return funcs, nil
+}
+
+// findMainFunc locates the "main" function among the program's SSA packages.
+func findMainFunc(pkgs []*ssa.Package) *ssa.Function {
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Pkg.Name() != "main" {
+			continue
+		}
+		if fn := pkg.Func("main"); fn != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// pruneToRoots returns a graph containing only nodes reachable from the
+// functions named in roots. A root matches either by its fully-qualified
+// RelString (e.g. "pkg.Name" or "(*pkg.T).Method") or, since callers
+// typically only know a bare function name, by fn.Name() alone. It is an
+// error if a requested root doesn't match any function in the graph, so a
+// typo or an unexported/unreachable name fails loudly instead of silently
+// producing an empty graph.
+func pruneToRoots(graph *callgraph.Graph, roots []string) (*callgraph.Graph, error) {
+	rootSet := make(map[string]bool, len(roots))
+	for _, r := range roots {
+		rootSet[r] = true
+	}
+	matched := make(map[string]bool, len(roots))
+
+	reachable := make(map[*callgraph.Node]bool)
+	var visit func(n *callgraph.Node)
+	visit = func(n *callgraph.Node) {
+		if reachable[n] {
+			return
+		}
+		reachable[n] = true
+		for _, edge := range n.Out {
+			visit(edge.Callee)
+		}
+	}
+	for fn, node := range graph.Nodes {
+		if fn == nil {
+			continue
+		}
+		if rootSet[fn.RelString(nil)] {
+			matched[fn.RelString(nil)] = true
+			visit(node)
+		} else if rootSet[fn.Name()] {
+			matched[fn.Name()] = true
+			visit(node)
+		}
+	}
+
+	for _, r := range roots {
+		if !matched[r] {
+			return nil, fmt.Errorf("--roots: no function named %q found in the call graph", r)
+		}
+	}
+
+	pruned := &callgraph.Graph{Nodes: make(map[*ssa.Function]*callgraph.Node)}
+	for fn, node := range graph.Nodes {
+		if reachable[node] {
+			pruned.Nodes[fn] = node
+		}
+	}
+	return pruned, nil
+}
+
+// OffsetRequest is the stdin payload for position-based code-action commands
+// such as fill-struct and fill-returns. Offset is a 0-based byte offset.
+type OffsetRequest struct {
+	Source string `json:"source"`
+	Offset int    `json:"offset"`
+}
+
+// Edit is a single textual replacement, expressed as a byte-offset span into
+// the original source plus the text that should replace it.
+type Edit struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"new_text"`
+}
+
+// Patch is an editor-consumable code-action result: a human-readable
+// unified-diff-style rendering plus the structured edits that produced it.
+type Patch struct {
+	Diff  string `json:"diff"`
+	Edits []Edit `json:"edits"`
+}
+
+// typeCheckSource parses and type-checks a single-file source using only the
+// standard library importer, returning enough to resolve composite literal
+// and function signature types.
+func typeCheckSource(source string) (*token.FileSet, *ast.File, *types.Info, *types.Package, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to parse Go source: %w", err)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	pkg, _ := conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+	// conf.Check returns a non-nil error for any type error in the file, but
+	// leaves info populated with everything it managed to resolve, which is
+	// good enough for locating the composite literal or return statement in
+	// question even when unrelated parts of the file don't type-check.
+	return fset, file, info, pkg, nil
+}
+
+// fillStruct resolves the composite literal at offset and returns a patch
+// that keeps every field already set by key and adds a zero-value
+// initializer for each remaining field.
+func fillStruct(source string, offset int) (Patch, error) {
+	fset, file, info, pkg, err := typeCheckSource(source)
+	if err != nil {
+		return Patch{}, err
+	}
+	target := fset.File(file.Pos()).Pos(offset)
+
+	lit := findCompositeLitAt(file, target)
+	if lit == nil {
+		return Patch{}, fmt.Errorf("no composite literal found at offset %d", offset)
+	}
+
+	t := info.TypeOf(lit)
+	if t == nil {
+		return Patch{}, fmt.Errorf("could not resolve the type of the composite literal")
+	}
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return Patch{}, fmt.Errorf("type %s is not a struct", t)
+	}
+
+	present := make(map[string]bool)
+	var existing []string
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if ident, ok := kv.Key.(*ast.Ident); ok {
+			present[ident.Name] = true
+			existing = append(existing, fmt.Sprintf("%s: %s", ident.Name, exprText(fset, kv.Value)))
+		}
+	}
+
+	seen := map[*types.Named]bool{}
+	if named, ok := t.(*types.Named); ok {
+		seen[named] = true
+	}
+	fields := append([]string(nil), existing...)
+	for i := 0; i < structType.NumFields(); i++ {
+		f := structType.Field(i)
+		if present[f.Name()] {
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s: %s", f.Name(), zeroValueExpr(f.Type(), pkg, seen)))
+	}
+
+	typeText := exprText(fset, lit.Type)
+	if typeText == "" {
+		typeText = types.TypeString(t, types.RelativeTo(pkg))
+	}
+	newText := fmt.Sprintf("%s{%s}", typeText, "\n\t"+strings.Join(fields, ",\n\t")+",\n")
+
+	start, end := fset.Position(lit.Pos()).Offset, fset.Position(lit.End()).Offset
+	edit := Edit{Start: start, End: end, NewText: newText}
+	return Patch{Diff: unifiedEdit(source, edit), Edits: []Edit{edit}}, nil
+}
+
+// findCompositeLitAt returns the innermost *ast.CompositeLit containing pos.
+func findCompositeLitAt(file *ast.File, pos token.Pos) *ast.CompositeLit {
+	var found *ast.CompositeLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil || n.Pos() > pos || pos > n.End() {
+			return false
+		}
+		if lit, ok := n.(*ast.CompositeLit); ok {
+			found = lit
+		}
+		return true
+	})
+	return found
+}
+
+// fillReturns resolves the return statement at offset and returns a patch
+// that appends zero values for any missing result values, preserving
+// already-present expressions in order.
+func fillReturns(source string, offset int) (Patch, error) {
+	fset, file, info, pkg, err := typeCheckSource(source)
+	if err != nil {
+		return Patch{}, err
+	}
+	target := fset.File(file.Pos()).Pos(offset)
+
+	ret, results := findReturnAt(file, target)
+	if ret == nil {
+		return Patch{}, fmt.Errorf("no return statement found at offset %d", offset)
+	}
+	if results == nil {
+		return Patch{}, fmt.Errorf("enclosing function has no declared results")
+	}
+
+	want := results.NumFields()
+	have := len(ret.Results)
+	if have >= want {
+		return Patch{}, fmt.Errorf("return statement already has %d of %d expected values", have, want)
+	}
+
+	exprs := make([]string, 0, want)
+	for _, r := range ret.Results {
+		exprs = append(exprs, exprText(fset, r))
+	}
+	seen := map[*types.Named]bool{}
+	idx := 0
+	for _, field := range results.List {
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for range names {
+			if idx >= have {
+				t := info.TypeOf(field.Type)
+				exprs = append(exprs, zeroValueExpr(t, pkg, seen))
+			}
+			idx++
+		}
+	}
+
+	newText := "return " + strings.Join(exprs, ", ")
+	start, end := fset.Position(ret.Pos()).Offset, fset.Position(ret.End()).Offset
+	edit := Edit{Start: start, End: end, NewText: newText}
+	return Patch{Diff: unifiedEdit(source, edit), Edits: []Edit{edit}}, nil
+}
+
+// findReturnAt locates the *ast.ReturnStmt enclosing pos along with the
+// result field list of its enclosing function (FuncDecl or FuncLit).
+func findReturnAt(file *ast.File, pos token.Pos) (*ast.ReturnStmt, *ast.FieldList) {
+	var ret *ast.ReturnStmt
+	var results *ast.FieldList
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil || n.Pos() > pos || pos > n.End() {
+			return false
+		}
+		switch d := n.(type) {
+		case *ast.FuncDecl:
+			results = d.Type.Results
+		case *ast.FuncLit:
+			results = d.Type.Results
+		case *ast.ReturnStmt:
+			ret = d
+		}
+		return true
+	})
+	return ret, results
+}
+
+// zeroValueExpr renders the zero-value expression for t, expanding
+// struct-typed fields into nested keyed literals. seen guards against
+// infinite recursion through self-referential named types.
+func zeroValueExpr(t types.Type, pkg *types.Package, seen map[*types.Named]bool) string {
+	if t == nil {
+		return "nil"
+	}
+	switch u := t.(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		default:
+			return "nil"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil"
+	case *types.Array:
+		return fmt.Sprintf("[%d]%s{}", u.Len(), types.TypeString(u.Elem(), types.RelativeTo(pkg)))
+	case *types.TypeParam:
+		return fmt.Sprintf("*new(%s)", types.TypeString(u, types.RelativeTo(pkg)))
+	case *types.Named:
+		if seen[u] {
+			return types.TypeString(u, types.RelativeTo(pkg)) + "{}"
+		}
+		structType, ok := u.Underlying().(*types.Struct)
+		if !ok {
+			seen[u] = true
+			defer delete(seen, u)
+			return zeroValueExpr(u.Underlying(), pkg, seen)
+		}
+		seen[u] = true
+		defer delete(seen, u)
+		fields := make([]string, 0, structType.NumFields())
+		for i := 0; i < structType.NumFields(); i++ {
+			f := structType.Field(i)
+			fields = append(fields, fmt.Sprintf("%s: %s", f.Name(), zeroValueExpr(f.Type(), pkg, seen)))
+		}
+		return fmt.Sprintf("%s{%s}", types.TypeString(u, types.RelativeTo(pkg)), strings.Join(fields, ", "))
+	case *types.Struct:
+		fields := make([]string, 0, u.NumFields())
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			fields = append(fields, fmt.Sprintf("%s: %s", f.Name(), zeroValueExpr(f.Type(), pkg, seen)))
+		}
+		return fmt.Sprintf("%s{%s}", types.TypeString(u, types.RelativeTo(pkg)), strings.Join(fields, ", "))
+	default:
+		return "nil"
+	}
+}
+
+// exprText renders an AST expression back to source text.
+func exprText(fset *token.FileSet, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// unifiedEdit renders a minimal unified-diff-style view of a single edit:
+// the replaced line range shown as removed, the new text shown as added.
+func unifiedEdit(source string, edit Edit) string {
+	startLine := strings.Count(source[:edit.Start], "\n") + 1
+	endLine := strings.Count(source[:edit.End], "\n") + 1
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "@@ -%d,%d +%d @@\n", startLine, endLine-startLine+1, startLine)
+	for _, line := range strings.Split(source[edit.Start:edit.End], "\n") {
+		fmt.Fprintf(&buf, "-%s\n", line)
+	}
+	for _, line := range strings.Split(edit.NewText, "\n") {
+		fmt.Fprintf(&buf, "+%s\n", line)
+	}
+	return buf.String()
+}
+
+// stringSliceFlag collects repeated occurrences of a flag, e.g. multiple
+// "--func pkg.Name" arguments, into a slice.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string { return strings.Join(*s, ",") }
+func (s *stringSliceFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+var formatVerbRE = regexp.MustCompile(`%[+\-# 0-9.]*[a-zA-Z%]`)
+
+// MessageArg describes one positional argument of an extracted message,
+// with its static Go type as seen at the call site.
+type MessageArg struct {
+	Type string `json:"type"`
+}
+
+// Message is a deduplicated user-facing string pulled from a translation
+// call, together with every place it occurs.
+type Message struct {
+	Message   string       `json:"message"`
+	Args      []MessageArg `json:"args"`
+	Note      string       `json:"note,omitempty"`
+	Locations []Location   `json:"locations"`
+}
+
+// ExtractDiagnostic records a call site whose format argument could not be
+// resolved to a compile-time constant.
+type ExtractDiagnostic struct {
+	Function string   `json:"function"`
+	Location Location `json:"location"`
+	Reason   string   `json:"reason"`
+}
+
+// ExtractMessagesResult is the output of the extract-messages command.
+type ExtractMessagesResult struct {
+	Messages    []Message           `json:"messages"`
+	Diagnostics []ExtractDiagnostic `json:"diagnostics"`
+}
+
+// extractMessages scans pkgPath for calls to the designated translation
+// functions (fmt.Sprintf/fmt.Printf plus any --func pkg.Name) and builds a
+// catalog of their format strings, deduplicated by (message, note).
+func extractMessages(pkgPath string, extraFuncs []string) (ExtractMessagesResult, error) {
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil {
+		return ExtractMessagesResult{}, fmt.Errorf("failed to load package %q: %w", pkgPath, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return ExtractMessagesResult{}, fmt.Errorf("package %q has type errors", pkgPath)
+	}
+
+	prog, _ := ssautil.AllPackages(pkgs, ssa.SanityCheckFunctions)
+	prog.Build()
+	cg := cha.CallGraph(prog)
+
+	targets := append([]string{"fmt.Sprintf", "fmt.Printf"}, extraFuncs...)
+	targetSet := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		targetSet[t] = true
+	}
+
+	commentMaps := buildCommentMaps(pkgs)
+
+	requestedPkgs := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		requestedPkgs[p.PkgPath] = true
+	}
+
+	byKey := make(map[string]*Message)
+	var order []string
+	var diags []ExtractDiagnostic
+
+	// Walk every function discovered across the whole program (not just the
+	// initially-requested packages): cha.CallGraph builds nodes for callees
+	// like fmt.Sprintf too, and that's where the call sites we care about
+	// live, in node.In.
+	for fn, node := range cg.Nodes {
+		if fn == nil || !targetSet[fn.RelString(nil)] {
+			continue
+		}
+		for _, edge := range node.In {
+			caller := edge.Caller.Func
+			if caller == nil || caller.Pkg == nil || !requestedPkgs[caller.Pkg.Pkg.Path()] {
+				// Only call sites inside the requested packages are extracted.
+				continue
+			}
+			call := edge.Site.Common()
+			if len(call.Args) == 0 {
+				continue
+			}
+			pos := prog.Fset.Position(edge.Site.Pos())
+			loc := Location{StartLine: pos.Line, StartColumn: pos.Column - 1}
+
+			formatArg := call.Args[0]
+			sconst, ok := formatArg.(*ssa.Const)
+			if !ok || sconst.Value == nil || sconst.Value.Kind() != constant.String {
+				diags = append(diags, ExtractDiagnostic{
+					Function: fn.RelString(nil),
+					Location: loc,
+					Reason:   "format argument is not a compile-time constant",
+				})
+				continue
+			}
+			message := constant.StringVal(sconst.Value)
+
+			variadic := variadicElements(call.Args[1:])
+			verbs := formatVerbRE.FindAllString(message, -1)
+			args := make([]MessageArg, 0, len(verbs))
+			i := 0
+			for _, verb := range verbs {
+				if verb == "%%" {
+					continue
+				}
+				if i < len(variadic) {
+					args = append(args, MessageArg{Type: variadic[i].Type().String()})
+				} else {
+					args = append(args, MessageArg{Type: "unknown"})
+				}
+				i++
+			}
+
+			note := translatorNoteAt(commentMaps, prog.Fset, edge.Site.Pos())
+			key := message + "\x00" + note
+			if existing, ok := byKey[key]; ok {
+				existing.Locations = append(existing.Locations, loc)
+				continue
+			}
+			msg := &Message{Message: message, Args: args, Note: note, Locations: []Location{loc}}
+			byKey[key] = msg
+			order = append(order, key)
+		}
+	}
+
+	messages := make([]Message, 0, len(order))
+	for _, key := range order {
+		messages = append(messages, *byKey[key])
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Message < messages[j].Message })
+
+	return ExtractMessagesResult{Messages: messages, Diagnostics: diags}, nil
+}
+
+// variadicElements recovers the individual values packed into a call's
+// trailing `args ...interface{}` slice. This is a best-effort walk of the
+// common `ssa.MakeInterface` + `ssa.Slice`-over-`ssa.Alloc` pattern the
+// compiler emits for an implicit variadic argument pack; it returns as many
+// elements as it can confidently resolve and stops at the first one it
+// can't, rather than guessing.
+func variadicElements(args []ssa.Value) []ssa.Value {
+	if len(args) != 1 {
+		return args
+	}
+	sl, ok := args[0].(*ssa.Slice)
+	if !ok {
+		return nil
+	}
+	alloc, ok := sl.X.(*ssa.Alloc)
+	if !ok {
+		return nil
+	}
+
+	// The compiler lowers an implicit `f(x, y)` variadic pack to a `new
+	// [N]any` alloc, one `*ssa.IndexAddr` per slot, and a `*ssa.Store` into
+	// each of those IndexAddrs — the stores are referrers of the IndexAddr,
+	// not of the alloc itself.
+	byIndex := make(map[int64]ssa.Value)
+	maxIndex := int64(-1)
+	for _, ref := range *alloc.Referrers() {
+		addr, ok := ref.(*ssa.IndexAddr)
+		if !ok {
+			continue
+		}
+		idxConst, ok := addr.Index.(*ssa.Const)
+		if !ok || idxConst.Value == nil {
+			continue
+		}
+		i, ok := constant.Int64Val(idxConst.Value)
+		if !ok {
+			continue
+		}
+		for _, addrRef := range *addr.Referrers() {
+			store, ok := addrRef.(*ssa.Store)
+			if !ok || store.Addr != addr {
+				continue
+			}
+			val := store.Val
+			if iface, ok := val.(*ssa.MakeInterface); ok {
+				val = iface.X
+			}
+			byIndex[i] = val
+			if i > maxIndex {
+				maxIndex = i
+			}
+		}
+	}
+
+	elems := make([]ssa.Value, 0, maxIndex+1)
+	for i := int64(0); i <= maxIndex; i++ {
+		v, ok := byIndex[i]
+		if !ok {
+			// A gap means we couldn't confidently resolve every slot;
+			// return only the prefix we're sure of rather than guessing.
+			return elems
+		}
+		elems = append(elems, v)
+	}
+	return elems
+}
+
+// buildCommentMaps indexes each loaded package's comment groups by file so
+// translatorNoteAt can look up the note preceding a call site.
+func buildCommentMaps(pkgs []*packages.Package) map[string]*ast.CommentMap {
+	maps := make(map[string]*ast.CommentMap)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			cm := ast.NewCommentMap(pkg.Fset, file, file.Comments)
+			maps[pkg.Fset.Position(file.Pos()).Filename] = &cm
+		}
+	}
+	return maps
+}
+
+// translatorNoteAt returns the text of the comment immediately preceding the
+// line at pos, treated as a translator note, or "" if there isn't one.
+func translatorNoteAt(maps map[string]*ast.CommentMap, fset *token.FileSet, pos token.Pos) string {
+	filename := fset.Position(pos).Filename
+	cm, ok := maps[filename]
+	if !ok {
+		return ""
+	}
+	line := fset.Position(pos).Line
+	var best *ast.CommentGroup
+	for _, groups := range *cm {
+		for _, g := range groups {
+			if fset.Position(g.End()).Line == line-1 {
+				best = g
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return strings.TrimSpace(best.Text())
+}
+
+// usedAnnotationRE matches the //typemill:used escape hatch comment that
+// marks a symbol as intentionally used (e.g. referenced only via reflection
+// or cgo).
+var usedAnnotationRE = regexp.MustCompile(`//\s*typemill:used\b`)
+
+// hasUsedAnnotation reports whether doc carries a //typemill:used comment.
+// It scans the raw comment list rather than doc.Text(), since Text() strips
+// any line whose body has the "word:word" directive shape (Go's own
+// convention for //go:noinline and friends) — and typemill:used matches
+// that shape, so it would always be stripped before the regex ever saw it.
+func hasUsedAnnotation(doc *ast.CommentGroup) bool {
+	for _, c := range doc.List {
+		if usedAnnotationRE.MatchString(c.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+// objectEdges maps a definition to every object its own declaration refers
+// to, forming the directed object graph unused-symbols does reachability
+// over: an edge from A to B means "A's definition references B".
+type objectEdges map[types.Object][]types.Object
+
+// unusedSymbols type-checks pkgPath, builds a directed graph of types.Objects
+// (an edge from a definition to each object it references, in the style of
+// honnef.co/go/tools/unused), seeds the "used" set, and reports every
+// definition that BFS from the seeds never reaches.
+//
+// scope controls both how much source is analyzed and how aggressively
+// exported symbols are assumed used from outside it: "module" loads the
+// whole package pattern and does not seed exported symbols (an
+// exported-but-unreferenced symbol is genuinely dead if the whole
+// module is visible); "package" loads the package pattern and also seeds
+// every exported symbol other than struct fields of its non-main packages,
+// since they form a public
+// API that may be called from elsewhere; "file" analyzes a single Go source
+// file in isolation (no sibling files, no non-stdlib imports) and applies
+// the same exported-symbol seeding, since callers outside that one file
+// can't be observed.
+func unusedSymbols(pkgPath string, scope string, debugGraphPath string) ([]SymbolInfo, error) {
+	objects := make(map[types.Object]SymbolInfo)
+	edges := make(objectEdges)
+	annotatedUsed := make(map[types.Object]bool)
+	var typesPkgs []*types.Package
+
+	if scope == "file" {
+		src, err := os.ReadFile(pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", pkgPath, err)
+		}
+		fset, file, info, pkg, err := typeCheckSource(string(src))
+		if err != nil {
+			return nil, err
+		}
+		collectDeclObjects(fset, info, file, objects, edges, annotatedUsed)
+		if pkg != nil {
+			typesPkgs = append(typesPkgs, pkg)
+		}
+	} else {
+		cfg := &packages.Config{Mode: packages.LoadAllSyntax}
+		pkgs, err := packages.Load(cfg, pkgPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load package %q: %w", pkgPath, err)
+		}
+		if packages.PrintErrors(pkgs) > 0 {
+			return nil, fmt.Errorf("package %q has type errors", pkgPath)
+		}
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Syntax {
+				collectDeclObjects(pkg.Fset, pkg.TypesInfo, file, objects, edges, annotatedUsed)
+			}
+			typesPkgs = append(typesPkgs, pkg.Types)
+		}
+	}
+
+	seeds := seedObjects(typesPkgs, objects, scope, annotatedUsed)
+	reachable := bfsReachable(seeds, edges)
+
+	if debugGraphPath != "" {
+		if err := writeGraphDOT(debugGraphPath, objects, edges); err != nil {
+			return nil, fmt.Errorf("failed to write debug graph: %w", err)
+		}
+	}
+
+	var unused []SymbolInfo
+	for obj, info := range objects {
+		if !reachable[obj] {
+			unused = append(unused, info)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		if unused[i].Location.StartLine != unused[j].Location.StartLine {
+			return unused[i].Location.StartLine < unused[j].Location.StartLine
+		}
+		return unused[i].Name < unused[j].Name
+	})
+	return unused, nil
+}
+
+// collectDeclObjects records every top-level definition (funcs, methods,
+// types, vars, consts, struct fields) in file as a graph node, then walks
+// each definition's own syntax to add edges to whatever it references.
+func collectDeclObjects(fset *token.FileSet, info *types.Info, file *ast.File, objects map[types.Object]SymbolInfo, edges objectEdges, annotatedUsed map[types.Object]bool) {
+	record := func(obj types.Object, kind string, node ast.Node, doc *ast.CommentGroup) {
+		if obj == nil {
+			return
+		}
+		if _, ok := objects[obj]; ok {
+			return
+		}
+		pos := fset.Position(node.Pos())
+		endPos := fset.Position(node.End())
+		objects[obj] = SymbolInfo{
+			Name: obj.Name(),
+			Kind: kind,
+			Location: Location{
+				StartLine: pos.Line, StartColumn: pos.Column - 1,
+				EndLine: endPos.Line, EndColumn: endPos.Column - 1,
+			},
+		}
+		if doc != nil && hasUsedAnnotation(doc) {
+			annotatedUsed[obj] = true
+		}
+	}
+
+	addEdgesFrom := func(from types.Object, subtree ast.Node) {
+		if from == nil || subtree == nil {
+			return
+		}
+		ast.Inspect(subtree, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.Ident:
+				if used := info.Uses[x]; used != nil {
+					edges[from] = append(edges[from], used)
+				}
+			case *ast.SelectorExpr:
+				if sel, ok := info.Selections[x]; ok {
+					edges[from] = append(edges[from], sel.Obj())
+				}
+			}
+			return true
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			obj := info.Defs[d.Name]
+			kind := "function"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			record(obj, kind, d, d.Doc)
+			addEdgesFrom(obj, d.Type)
+			addEdgesFrom(obj, d.Body)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					obj := info.Defs[s.Name]
+					kind := "other"
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					record(obj, kind, s, d.Doc)
+					addEdgesFrom(obj, s.Type)
+
+					if structType, ok := s.Type.(*ast.StructType); ok {
+						for _, field := range structType.Fields.List {
+							for _, name := range field.Names {
+								fieldObj := info.Defs[name]
+								record(fieldObj, "field", field, field.Doc)
+								addEdgesFrom(fieldObj, field.Type)
+							}
+						}
+					}
+
+				case *ast.ValueSpec:
+					kind := "variable"
+					if d.Tok == token.CONST {
+						kind = "constant"
+					}
+					for _, name := range s.Names {
+						obj := info.Defs[name]
+						record(obj, kind, s, d.Doc)
+						addEdgesFrom(obj, s.Type)
+						for _, v := range s.Values {
+							addEdgesFrom(obj, v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// seedObjects returns the set of objects considered reachable a priori:
+// main/init, tests, exported non-field symbols of non-main packages (unless
+// scope is "module"), anything carrying a //typemill:used annotation, and
+// methods of types that satisfy an interface declared in one of pkgs.
+func seedObjects(pkgs []*types.Package, objects map[types.Object]SymbolInfo, scope string, annotatedUsed map[types.Object]bool) []types.Object {
+	var seeds []types.Object
+	autoSeedExported := scope != "module"
+
+	for obj, info := range objects {
+		switch {
+		case obj.Name() == "main" || obj.Name() == "init":
+			seeds = append(seeds, obj)
+		case strings.HasPrefix(obj.Name(), "Test") || strings.HasPrefix(obj.Name(), "Benchmark") || strings.HasPrefix(obj.Name(), "Example"):
+			seeds = append(seeds, obj)
+		case annotatedUsed[obj]:
+			seeds = append(seeds, obj)
+		case autoSeedExported && info.Kind != "field" && ast.IsExported(obj.Name()) && (obj.Pkg() == nil || obj.Pkg().Name() != "main"):
+			// Exported symbols are only a public API from outside a
+			// non-main package; a main package has no importers, so its
+			// exported identifiers are exactly as dead as unexported ones.
+			seeds = append(seeds, obj)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		seeds = append(seeds, interfaceSatisfyingMethods(pkg, objects)...)
+	}
+	return seeds
+}
+
+// interfaceSatisfyingMethods seeds every method of a concrete type that
+// satisfies some interface declared in the package, since such methods may
+// only ever be invoked through dynamic dispatch.
+func interfaceSatisfyingMethods(pkg *types.Package, objects map[types.Object]SymbolInfo) []types.Object {
+	var ifaces []*types.Interface
+	var named []*types.Named
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		n, ok := obj.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if iface, ok := n.Underlying().(*types.Interface); ok {
+			ifaces = append(ifaces, iface)
+		} else {
+			named = append(named, n)
+		}
+	}
+
+	var seeds []types.Object
+	for _, n := range named {
+		for _, iface := range ifaces {
+			if types.Implements(n, iface) || types.Implements(types.NewPointer(n), iface) {
+				for i := 0; i < n.NumMethods(); i++ {
+					if _, ok := objects[n.Method(i)]; ok {
+						seeds = append(seeds, n.Method(i))
+					}
+				}
+			}
+		}
+	}
+	return seeds
+}
+
+// bfsReachable walks edges outward from seeds and returns the set of every
+// object reached.
+func bfsReachable(seeds []types.Object, edges objectEdges) map[types.Object]bool {
+	reachable := make(map[types.Object]bool)
+	queue := append([]types.Object(nil), seeds...)
+	for _, s := range seeds {
+		reachable[s] = true
+	}
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[obj] {
+			if !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reachable
+}
+
+// writeGraphDOT dumps the object graph as Graphviz DOT for inspection.
+func writeGraphDOT(path string, objects map[types.Object]SymbolInfo, edges objectEdges) error {
+	var buf bytes.Buffer
+	buf.WriteString("digraph unused {\n")
+	for obj, info := range objects {
+		fmt.Fprintf(&buf, "  %q;\n", fmt.Sprintf("%s:%s", info.Kind, obj.Name()))
+	}
+	for from, tos := range edges {
+		fromInfo, ok := objects[from]
+		if !ok {
+			continue
+		}
+		for _, to := range tos {
+			toInfo, ok := objects[to]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&buf, "  %q -> %q;\n", fmt.Sprintf("%s:%s", fromInfo.Kind, from.Name()), fmt.Sprintf("%s:%s", toInfo.Kind, to.Name()))
+		}
+	}
+	buf.WriteString("}\n")
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <command>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  analyze-imports  Parse Go source from stdin and output import information as JSON\n")
+		fmt.Fprintf(os.Stderr, "  extract-symbols  Parse Go source from stdin and output symbol information as JSON\n")
+		fmt.Fprintf(os.Stderr, "  rewrite-imports  Read {\"source\",\"operations\"} JSON from stdin and output the rewritten source as JSON\n")
+		fmt.Fprintf(os.Stderr, "  find-enclosing   Read {\"source\",\"start\",\"end\"} JSON from stdin and output the enclosing AST node path as JSON\n")
+		fmt.Fprintf(os.Stderr, "  call-graph       Build a CHA/RTA call graph for a package and output it as JSON\n")
+		fmt.Fprintf(os.Stderr, "  fill-struct      Read {\"source\",\"offset\"} JSON from stdin and output a patch filling the composite literal\n")
+		fmt.Fprintf(os.Stderr, "  fill-returns     Read {\"source\",\"offset\"} JSON from stdin and output a patch filling the return statement\n")
+		fmt.Fprintf(os.Stderr, "  extract-messages Scan a package for translation calls and output a message catalog as JSON\n")
+		fmt.Fprintf(os.Stderr, "  unused-symbols   Report exported and unexported symbols that are never referenced as JSON\n")
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	switch command {
+	case "analyze-imports":
+		// Read source from stdin
+		sourceBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		source := string(sourceBytes)
+
+		// Analyze imports
+		imports, err := analyzeImports(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error analyzing imports: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Output as JSON
+		output, err := json.MarshalIndent(imports, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "extract-symbols":
+		// Read source from stdin
+		sourceBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		source := string(sourceBytes)
+
+		// Extract symbols
+		symbols, err := extractSymbols(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting symbols: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Output as JSON
+		output, err := json.MarshalIndent(symbols, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "rewrite-imports":
+		// Read the {source, operations} request from stdin
+		requestBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		var req RewriteImportsRequest
+		if err := json.Unmarshal(requestBytes, &req); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing request JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		result, err := rewriteImports(req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error rewriting imports: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "find-enclosing":
+		// Read the {source, start, end} request from stdin
+		requestBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		var req FindEnclosingRequest
+		if err := json.Unmarshal(requestBytes, &req); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing request JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		path, err := findEnclosingPath(req)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding enclosing node: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(path, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "call-graph":
+		fs := flag.NewFlagSet("call-graph", flag.ExitOnError)
+		rootsFlag := fs.String("roots", "", "comma-separated list of entry point function names to prune to")
+		rtaFlag := fs.Bool("rta", false, "use Rapid Type Analysis (requires a main function) instead of CHA")
+		if err := fs.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if fs.NArg() < 1 {
+			fmt.Fprintf(os.Stderr, "Usage: %s call-graph [--roots f1,f2] [--rta] <package-path>\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		var roots []string
+		if *rootsFlag != "" {
+			roots = strings.Split(*rootsFlag, ",")
+		}
+
+		funcs, err := buildCallGraph(fs.Arg(0), roots, *rtaFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error building call graph: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(funcs, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "fill-struct":
+		requestBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		var req OffsetRequest
+		if err := json.Unmarshal(requestBytes, &req); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing request JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		patch, err := fillStruct(req.Source, req.Offset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error filling struct literal: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(patch, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "fill-returns":
+		requestBytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
+
+		var req OffsetRequest
+		if err := json.Unmarshal(requestBytes, &req); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing request JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		patch, err := fillReturns(req.Source, req.Offset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error filling return statement: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(patch, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "extract-messages":
+		fs := flag.NewFlagSet("extract-messages", flag.ExitOnError)
+		var funcsFlag stringSliceFlag
+		fs.Var(&funcsFlag, "func", "additional pkg.Name translation function to scan for (repeatable)")
+		if err := fs.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if fs.NArg() < 1 {
+			fmt.Fprintf(os.Stderr, "Usage: %s extract-messages [--func pkg.Name] <package-path>\n", os.Args[0])
+			os.Exit(1)
+		}
+
+		result, err := extractMessages(fs.Arg(0), funcsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting messages: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(output))
+
+	case "unused-symbols":
+		fs := flag.NewFlagSet("unused-symbols", flag.ExitOnError)
+		scopeFlag := fs.String("scope", "package", "reachability scope: package|module (package path argument) or file (single .go file argument)")
+		debugGraphFlag := fs.String("debug-graph", "", "dump the object graph as DOT to this path")
+		if err := fs.Parse(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+			os.Exit(1)
+		}
+		if fs.NArg() < 1 {
+			fmt.Fprintf(os.Stderr, "Usage: %s unused-symbols [--scope package|module|file] [--debug-graph path] <package-path-or-file>\n", os.Args[0])
+			os.Exit(1)
+		}
+		switch *scopeFlag {
+		case "package", "module", "file":
+		default:
+			fmt.Fprintf(os.Stderr, "Invalid --scope %q: must be package, module, or file\n", *scopeFlag)
+			os.Exit(1)
+		}
+
+		unused, err := unusedSymbols(fs.Arg(0), *scopeFlag, *debugGraphFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error analyzing unused symbols: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := json.MarshalIndent(unused, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error marshaling JSON: %v\n", err)
 			os.Exit(1)